@@ -1,38 +1,153 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
 	"os"
 	"path/filepath"
-	"strings"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/bitrise-io/go-utils/log"
 	"github.com/bitrise-tools/go-steputils/input"
-	"github.com/bitrise-tools/goftp"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
 )
 
 // ConfigsModel ...
 type ConfigsModel struct {
-	HostName   string
-	Username   string
-	Password   string
-	SourcePath string
-	SourcePathFilter string
-	TargetPath string
-	DebugMode  bool
+	HostName           string
+	Username           string
+	Password           string
+	SourcePath         string
+	IncludeFilters     []string
+	ExcludeFilters     []string
+	FilterFrom         string
+	TargetPath         string
+	DebugMode          bool
+	FtpsMode           string
+	NoCheckCertificate bool
+	CaFile             string
+	Concurrency        int
+	Incremental        bool
+	Overwrite          string
+	DeleteExtraneous   bool
+	MaxRetries         int
+	InitialBackoff     time.Duration
+	MaxBackoff         time.Duration
+	Protocol           string
+	SSHPrivateKeyPath  string
+	SSHKeyPassphrase   string
+	SSHKnownHostsFile  string
+}
+
+const defaultConcurrency = 4
+
+// defaultOverwrite keeps incremental's size/mtime comparison in effect;
+// "always" bypasses it and re-uploads every file regardless of the remote state.
+const defaultOverwrite = "when_changed"
+
+const (
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 1 * time.Second
+	defaultMaxBackoff     = 30 * time.Second
+)
+
+// defaultProtocol keeps existing workflows on plain FTP; ftps and sftp are opt-in.
+const defaultProtocol = "ftp"
+
+// splitFilterList splits a newline-separated include_filters/exclude_filters
+// input into its individual glob patterns, skipping blank lines.
+func splitFilterList(raw string) []string {
+	var patterns []string
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns
 }
 
 func createConfigsModelFromEnvs() *ConfigsModel {
+	concurrency := defaultConcurrency
+	if v := os.Getenv("concurrency"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			concurrency = n
+		}
+	}
+
+	overwrite := os.Getenv("overwrite")
+	if overwrite == "" {
+		overwrite = defaultOverwrite
+	}
+
+	maxRetries := defaultMaxRetries
+	if v := os.Getenv("max_retries"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			maxRetries = n
+		}
+	}
+
+	initialBackoff := defaultInitialBackoff
+	if v := os.Getenv("initial_backoff"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			initialBackoff = d
+		}
+	}
+
+	maxBackoff := defaultMaxBackoff
+	if v := os.Getenv("max_backoff"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			maxBackoff = d
+		}
+	}
+
+	protocol := os.Getenv("protocol")
+	if protocol == "" {
+		protocol = defaultProtocol
+	}
+
+	ftpsMode := os.Getenv("ftps_mode")
+	if protocol == "ftps" && ftpsMode == "" {
+		ftpsMode = "explicit"
+	}
+
 	return &ConfigsModel{
-		HostName:   os.Getenv("hostname"),
-		Username:   os.Getenv("username"),
-		Password:   os.Getenv("password"),
-		SourcePath: os.Getenv("upload_source_path"),
-		SourcePathFilter: os.Getenv("upload_source_path_filter"),
-		TargetPath: os.Getenv("upload_target_path"),
-		DebugMode:  os.Getenv("debug_mode") == "true",
+		HostName:           os.Getenv("hostname"),
+		Username:           os.Getenv("username"),
+		Password:           os.Getenv("password"),
+		SourcePath:         os.Getenv("upload_source_path"),
+		IncludeFilters:     splitFilterList(os.Getenv("include_filters")),
+		ExcludeFilters:     splitFilterList(os.Getenv("exclude_filters")),
+		FilterFrom:         os.Getenv("filter_from"),
+		TargetPath:         os.Getenv("upload_target_path"),
+		DebugMode:          os.Getenv("debug_mode") == "true",
+		FtpsMode:           ftpsMode,
+		NoCheckCertificate: os.Getenv("no_check_certificate") == "true",
+		CaFile:             os.Getenv("ca_file"),
+		Concurrency:        concurrency,
+		Incremental:        os.Getenv("incremental") == "true",
+		Overwrite:          overwrite,
+		DeleteExtraneous:   os.Getenv("delete_extraneous") == "true",
+		MaxRetries:         maxRetries,
+		InitialBackoff:     initialBackoff,
+		MaxBackoff:         maxBackoff,
+		Protocol:           protocol,
+		SSHPrivateKeyPath:  os.Getenv("ssh_private_key_path"),
+		SSHKeyPassphrase:   os.Getenv("ssh_key_passphrase"),
+		SSHKnownHostsFile:  os.Getenv("ssh_known_hosts_file"),
 	}
 }
 
@@ -42,8 +157,24 @@ func (configs ConfigsModel) print() {
 	log.Printf("- Username: %s", input.SecureInput(configs.Username))
 	log.Printf("- Password: %s", input.SecureInput(configs.Password))
 	log.Printf("- SourcePath: %s", configs.SourcePath)
-	log.Printf("- SourcePathFilter: %s", configs.SourcePathFilter)
+	log.Printf("- IncludeFilters: %s", strings.Join(configs.IncludeFilters, ", "))
+	log.Printf("- ExcludeFilters: %s", strings.Join(configs.ExcludeFilters, ", "))
+	log.Printf("- FilterFrom: %s", configs.FilterFrom)
 	log.Printf("- TargetPath: %s", configs.TargetPath)
+	log.Printf("- FtpsMode: %s", configs.FtpsMode)
+	log.Printf("- NoCheckCertificate: %t", configs.NoCheckCertificate)
+	log.Printf("- CaFile: %s", configs.CaFile)
+	log.Printf("- Concurrency: %d", configs.Concurrency)
+	log.Printf("- Incremental: %t", configs.Incremental)
+	log.Printf("- Overwrite: %s", configs.Overwrite)
+	log.Printf("- DeleteExtraneous: %t", configs.DeleteExtraneous)
+	log.Printf("- MaxRetries: %d", configs.MaxRetries)
+	log.Printf("- InitialBackoff: %s", configs.InitialBackoff)
+	log.Printf("- MaxBackoff: %s", configs.MaxBackoff)
+	log.Printf("- Protocol: %s", configs.Protocol)
+	log.Printf("- SSHPrivateKeyPath: %s", configs.SSHPrivateKeyPath)
+	log.Printf("- SSHKeyPassphrase: %s", input.SecureInput(configs.SSHKeyPassphrase))
+	log.Printf("- SSHKnownHostsFile: %s", configs.SSHKnownHostsFile)
 }
 
 func failf(format string, v ...interface{}) {
@@ -76,15 +207,804 @@ func (configs ConfigsModel) validate() error {
 		return errors.New("no TargetPath parameter specified")
 	}
 
+	switch configs.Protocol {
+	case "", "ftp", "ftps", "sftp":
+	default:
+		return fmt.Errorf("invalid Protocol: %s, should be one of: ftp, ftps, sftp", configs.Protocol)
+	}
+
+	switch configs.FtpsMode {
+	case "", "off", "implicit", "explicit":
+	default:
+		return fmt.Errorf("invalid FtpsMode: %s, should be one of: off, implicit, explicit", configs.FtpsMode)
+	}
+
+	if configs.SSHPrivateKeyPath != "" {
+		if err := input.ValidateIfPathExists(configs.SSHPrivateKeyPath); err != nil {
+			return fmt.Errorf("SSHPrivateKeyPath's path(%s) doesn't exists", configs.SSHPrivateKeyPath)
+		}
+	}
+
+	if configs.SSHKnownHostsFile != "" {
+		if err := input.ValidateIfPathExists(configs.SSHKnownHostsFile); err != nil {
+			return fmt.Errorf("SSHKnownHostsFile's path(%s) doesn't exists", configs.SSHKnownHostsFile)
+		}
+	}
+
+	if configs.CaFile != "" {
+		if err := input.ValidateIfPathExists(configs.CaFile); err != nil {
+			return fmt.Errorf("CaFile's path(%s) doesn't exists", configs.CaFile)
+		}
+	}
+
+	if configs.FilterFrom != "" {
+		if err := input.ValidateIfPathExists(configs.FilterFrom); err != nil {
+			return fmt.Errorf("FilterFrom's path(%s) doesn't exists", configs.FilterFrom)
+		}
+	}
+
+	if configs.Concurrency < 0 {
+		return fmt.Errorf("concurrency must be >= 0 (0 means unlimited), got: %d", configs.Concurrency)
+	}
+
+	switch configs.Overwrite {
+	case "when_changed", "always":
+	default:
+		return fmt.Errorf("invalid Overwrite: %s, should be one of: when_changed, always", configs.Overwrite)
+	}
+
+	if configs.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must be >= 0, got: %d", configs.MaxRetries)
+	}
+
 	return nil
 }
 
 func (configs *ConfigsModel) cleanHostName() {
-	//clean hostname, removes ftp:// prefix and if no port given sets the default :21
+	//clean hostname, removes the scheme prefix and if no port given sets the
+	//protocol-appropriate default (21 plain/explicit FTP(S), 990 implicit FTPS, 22 SFTP)
 	configs.HostName = strings.TrimPrefix(configs.HostName, "ftp://")
+	configs.HostName = strings.TrimPrefix(configs.HostName, "ftps://")
+	configs.HostName = strings.TrimPrefix(configs.HostName, "sftp://")
 	if !strings.Contains(configs.HostName, ":") {
-		configs.HostName += ":21"
+		switch {
+		case configs.Protocol == "sftp":
+			configs.HostName += ":22"
+		case configs.Protocol == "ftps" && configs.FtpsMode == "implicit":
+			configs.HostName += ":990"
+		default:
+			configs.HostName += ":21"
+		}
+	}
+}
+
+// Transport is the protocol-agnostic surface sync() and copyFile() upload
+// through: *ftpClient (plain FTP and FTPS) and *sftpTransport (SFTP)
+// both implement it, selected by the Protocol input.
+type Transport interface {
+	Mkdir(path string) error
+	PutFile(localPath, remotePath string) error
+	Stat(path string) (os.FileInfo, error)
+	ReadDir(path string) ([]os.FileInfo, error)
+	Remove(path string) error
+	Close() error
+}
+
+// ftpSession is the subset of FTP control operations the step relies on.
+// tlsFTP implements it directly on top of crypto/tls (and, for plain FTP,
+// a bare net.Conn): the vendored goftp library's Size returns an int, not
+// the int64 the SIZE/MDTM incremental check needs, and it has no ModTime
+// or ReadDir of its own, so it's not used as a session implementation here.
+type ftpSession interface {
+	Login(user, pass string) error
+	Mkd(path string) error
+	Stor(path string, r io.Reader) error
+	Size(path string) (int64, error)
+	ModTime(path string) (time.Time, error)
+	ReadDir(path string) ([]os.FileInfo, error)
+	Dele(path string) error
+	Close() error
+}
+
+// tlsFTP is a minimal FTP client speaking the control channel over
+// crypto/tls for FTPS, or over a bare net.Conn for plain FTP when
+// tlsConfig is nil. It exists because the vendored goftp library can't
+// satisfy ftpSession as-is (see the comment there), so it's used for
+// every FtpsMode, not just implicit/explicit FTPS.
+type tlsFTP struct {
+	conn       *textproto.Conn
+	tlsConfig  *tls.Config
+	controlRaw net.Conn
+	debug      bool
+}
+
+// connectPlain dials a plain, unencrypted control channel for ordinary FTP.
+func connectPlain(configs ConfigsModel) (*tlsFTP, error) {
+	rawConn, err := net.Dial("tcp", configs.HostName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := textproto.NewConn(rawConn)
+	if _, _, err := conn.ReadCodeLine(220); err != nil {
+		return nil, fmt.Errorf("failed to read FTP banner: %s", err)
+	}
+
+	return &tlsFTP{conn: conn, controlRaw: rawConn, debug: configs.DebugMode}, nil
+}
+
+func buildTLSConfig(configs ConfigsModel) (*tls.Config, error) {
+	serverName := configs.HostName
+	if host, _, err := net.SplitHostPort(configs.HostName); err == nil {
+		serverName = host
+	}
+
+	tlsConfig := &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: configs.NoCheckCertificate,
+	}
+
+	if configs.CaFile != "" {
+		caCert, err := ioutil.ReadFile(configs.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CaFile: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse CaFile(%s) as a PEM certificate", configs.CaFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
+
+func logCipherSuite(debug bool, state tls.ConnectionState) {
+	if !debug {
+		return
+	}
+	log.Printf("- Negotiated TLS cipher suite: %s", tls.CipherSuiteName(state.CipherSuite))
+}
+
+// connectImplicitTLS dials the control channel straight into TLS, as implicit
+// FTPS servers (conventionally on port 990) expect.
+func connectImplicitTLS(configs ConfigsModel) (*tlsFTP, error) {
+	tlsConfig, err := buildTLSConfig(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	rawConn, err := tls.Dial("tcp", configs.HostName, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	logCipherSuite(configs.DebugMode, rawConn.ConnectionState())
+
+	client := &tlsFTP{conn: textproto.NewConn(rawConn), tlsConfig: tlsConfig, controlRaw: rawConn, debug: configs.DebugMode}
+	if _, _, err := client.conn.ReadCodeLine(220); err != nil {
+		return nil, fmt.Errorf("failed to read FTPS banner: %s", err)
+	}
+
+	if err := client.secureDataChannel(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// connectExplicitTLS dials a plain control channel, then upgrades it to TLS
+// with AUTH TLS before any credentials are sent.
+func connectExplicitTLS(configs ConfigsModel) (*tlsFTP, error) {
+	tlsConfig, err := buildTLSConfig(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	rawConn, err := net.Dial("tcp", configs.HostName)
+	if err != nil {
+		return nil, err
+	}
+
+	plainConn := textproto.NewConn(rawConn)
+	if _, _, err := plainConn.ReadCodeLine(220); err != nil {
+		return nil, fmt.Errorf("failed to read FTP banner: %s", err)
+	}
+
+	id, err := plainConn.Cmd("AUTH TLS")
+	if err != nil {
+		return nil, err
+	}
+	plainConn.StartResponse(id)
+	_, _, err = plainConn.ReadCodeLine(234)
+	plainConn.EndResponse(id)
+	if err != nil {
+		return nil, fmt.Errorf("server rejected AUTH TLS: %s", err)
+	}
+
+	tlsConn := tls.Client(rawConn, tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, fmt.Errorf("TLS handshake failed: %s", err)
+	}
+	logCipherSuite(configs.DebugMode, tlsConn.ConnectionState())
+
+	client := &tlsFTP{conn: textproto.NewConn(tlsConn), tlsConfig: tlsConfig, controlRaw: tlsConn, debug: configs.DebugMode}
+	if err := client.secureDataChannel(); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (c *tlsFTP) cmd(expectCode int, format string, args ...interface{}) (string, error) {
+	id, err := c.conn.Cmd(format, args...)
+	if err != nil {
+		return "", err
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+	_, msg, err := c.conn.ReadCodeLine(expectCode)
+	return msg, err
+}
+
+// secureDataChannel issues PBSZ 0 / PROT P so data connections (used for
+// directory listings and transfers) are also protected, as required by
+// RFC 4217.
+func (c *tlsFTP) secureDataChannel() error {
+	if _, err := c.cmd(200, "PBSZ 0"); err != nil {
+		return fmt.Errorf("PBSZ failed: %s", err)
+	}
+	if _, err := c.cmd(200, "PROT P"); err != nil {
+		return fmt.Errorf("PROT failed: %s", err)
+	}
+	return nil
+}
+
+// Login ...
+func (c *tlsFTP) Login(user, pass string) error {
+	if _, err := c.cmd(331, "USER %s", user); err != nil {
+		return fmt.Errorf("USER failed: %w", err)
+	}
+	if _, err := c.cmd(230, "PASS %s", pass); err != nil {
+		return fmt.Errorf("PASS failed: %w", err)
+	}
+	return nil
+}
+
+// Mkd ...
+func (c *tlsFTP) Mkd(path string) error {
+	_, err := c.cmd(257, "MKD %s", path)
+	return err
+}
+
+func (c *tlsFTP) pasv() (net.Conn, error) {
+	msg, err := c.cmd(227, "PASV")
+	if err != nil {
+		return nil, err
+	}
+
+	start := strings.Index(msg, "(")
+	end := strings.Index(msg, ")")
+	if start < 0 || end < 0 || end <= start {
+		return nil, fmt.Errorf("unexpected PASV response: %s", msg)
+	}
+
+	parts := strings.Split(msg[start+1:end], ",")
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("unexpected PASV response: %s", msg)
+	}
+
+	p1, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return nil, err
+	}
+	p2, err := strconv.Atoi(parts[5])
+	if err != nil {
+		return nil, err
+	}
+
+	addr := fmt.Sprintf("%s:%d", strings.Join(parts[:4], "."), p1<<8+p2)
+
+	dataConn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.tlsConfig == nil {
+		return dataConn, nil
+	}
+	return tls.Client(dataConn, c.tlsConfig), nil
+}
+
+// Stor ...
+func (c *tlsFTP) Stor(path string, r io.Reader) error {
+	dataConn, err := c.pasv()
+	if err != nil {
+		return err
+	}
+
+	id, err := c.conn.Cmd("STOR %s", path)
+	if err != nil {
+		return err
+	}
+	c.conn.StartResponse(id)
+	if _, _, err := c.conn.ReadCodeLine(150); err != nil {
+		c.conn.EndResponse(id)
+		return fmt.Errorf("STOR rejected: %w", err)
+	}
+
+	_, copyErr := io.Copy(dataConn, r)
+	closeErr := dataConn.Close()
+	if copyErr != nil {
+		c.conn.EndResponse(id)
+		return copyErr
+	}
+	if closeErr != nil {
+		c.conn.EndResponse(id)
+		return closeErr
+	}
+
+	_, _, err = c.conn.ReadCodeLine(226)
+	c.conn.EndResponse(id)
+	return err
+}
+
+// Size ...
+func (c *tlsFTP) Size(path string) (int64, error) {
+	msg, err := c.cmd(213, "SIZE %s", path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(msg), 10, 64)
+}
+
+// ModTime ...
+func (c *tlsFTP) ModTime(path string) (time.Time, error) {
+	msg, err := c.cmd(213, "MDTM %s", path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse("20060102150405", strings.TrimSpace(msg))
+}
+
+// Dele ...
+func (c *tlsFTP) Dele(path string) error {
+	_, err := c.cmd(250, "DELE %s", path)
+	return err
+}
+
+// ReadDir lists path over a PASV data connection and parses the Unix-style
+// LIST output into os.FileInfo.
+func (c *tlsFTP) ReadDir(path string) ([]os.FileInfo, error) {
+	dataConn, err := c.pasv()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := c.conn.Cmd("LIST %s", path)
+	if err != nil {
+		return nil, err
+	}
+	c.conn.StartResponse(id)
+	defer c.conn.EndResponse(id)
+
+	if _, _, err := c.conn.ReadCodeLine(150); err != nil {
+		return nil, fmt.Errorf("LIST rejected: %s", err)
+	}
+
+	raw, err := ioutil.ReadAll(dataConn)
+	_ = dataConn.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, err := c.conn.ReadCodeLine(226); err != nil {
+		return nil, err
+	}
+
+	var infos []os.FileInfo
+	for _, line := range strings.Split(strings.TrimRight(string(raw), "\r\n"), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		info, err := parseUnixListLine(line)
+		if err != nil {
+			if c.debug {
+				log.Warnf("Skipping unparsable LIST line: %s", line)
+			}
+			continue
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// remoteFileInfo implements os.FileInfo for a single parsed LIST entry, or
+// for the synthetic SIZE/MDTM pair ftpClient.Stat assembles.
+type remoteFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+func (fi remoteFileInfo) Name() string       { return fi.name }
+func (fi remoteFileInfo) Size() int64        { return fi.size }
+func (fi remoteFileInfo) Mode() os.FileMode  { return 0 }
+func (fi remoteFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi remoteFileInfo) IsDir() bool        { return fi.isDir }
+func (fi remoteFileInfo) Sys() interface{}   { return nil }
+
+// parseUnixListLine parses a single line of a classic Unix-style FTP LIST
+// response, e.g.: "-rw-r--r-- 1 owner group 1234 Jan 02 15:04 filename.txt"
+func parseUnixListLine(line string) (os.FileInfo, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 9 {
+		return nil, fmt.Errorf("unexpected LIST line: %s", line)
+	}
+
+	size, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.Join(fields[8:], " ")
+
+	return remoteFileInfo{
+		name:  name,
+		size:  size,
+		isDir: strings.HasPrefix(fields[0], "d"),
+	}, nil
+}
+
+// Close ...
+func (c *tlsFTP) Close() error {
+	_, _ = c.cmd(221, "QUIT")
+	return c.controlRaw.Close()
+}
+
+// connect dials the control channel for configs.Protocol (sftp is handled by
+// connectTransport instead, so this only ever sees ftp/ftps). FtpsMode is
+// only honored when Protocol is explicitly "ftps", so the two inputs can't
+// disagree: a leftover ftps_mode from before Protocol existed can't silently
+// upgrade a protocol=ftp run to FTPS.
+func connect(configs ConfigsModel) (ftpSession, error) {
+	ftpsMode := configs.FtpsMode
+	if configs.Protocol != "ftps" {
+		ftpsMode = "off"
+	}
+
+	switch ftpsMode {
+	case "implicit":
+		return connectImplicitTLS(configs)
+	case "explicit":
+		return connectExplicitTLS(configs)
+	default:
+		return connectPlain(configs)
+	}
+}
+
+func connectAndLogin(configs ConfigsModel) (ftpSession, error) {
+	ftp, err := connect(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ftp.Login(configs.Username, configs.Password); err != nil {
+		_ = ftp.Close()
+		return nil, err
+	}
+
+	return ftp, nil
+}
+
+var transientReplyPattern = regexp.MustCompile(`^4\d{2}[\s-]`)
+
+// isTransientErr reports whether err looks like a connection hiccup or an
+// FTP 4xx transient reply, as opposed to a permanent failure (bad
+// credentials, permission denied, disk full) that retrying won't fix.
+func isTransientErr(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+
+	// Some FTP client libraries surface transient replies as plain
+	// "421 ..." strings rather than a typed *textproto.Error.
+	return transientReplyPattern.MatchString(err.Error())
+}
+
+// ftpClient wraps an ftpSession and retries Login/Mkd/Stor with exponential
+// backoff on transient errors, reconnecting the underlying session first
+// since a dead control channel won't recover on its own.
+type ftpClient struct {
+	configs ConfigsModel
+	session ftpSession
+}
+
+func newFtpClient(configs ConfigsModel) (*ftpClient, error) {
+	c := &ftpClient{configs: configs}
+	if err := c.withRetry(nil, func() error {
+		session, err := connectAndLogin(configs)
+		if err != nil {
+			return err
+		}
+		c.session = session
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *ftpClient) reconnect() error {
+	if c.session != nil {
+		_ = c.session.Close()
+	}
+	session, err := connectAndLogin(c.configs)
+	if err != nil {
+		return err
+	}
+	c.session = session
+	return nil
+}
+
+// withRetry runs op, retrying on transient errors with exponential backoff
+// up to MaxRetries times. reconnect (nil for the very first connect) is
+// called before each retry so the next attempt uses a live session.
+func (c *ftpClient) withRetry(reconnect func() error, op func() error) error {
+	return withRetry(c.configs, reconnect, op)
+}
+
+// withRetry runs op, retrying on transient errors with exponential backoff
+// up to configs.MaxRetries times. reconnect (nil for the very first connect)
+// is called before each retry so the next attempt uses a live connection.
+// Shared by ftpClient and sftpTransport so both backends retry the same way.
+func withRetry(configs ConfigsModel, reconnect func() error, op func() error) error {
+	backoff := configs.InitialBackoff
+	var lastErr error
+
+	for attempt := 0; attempt <= configs.MaxRetries; attempt++ {
+		lastErr = op()
+		if lastErr == nil {
+			return nil
+		}
+		if !isTransientErr(lastErr) {
+			return lastErr
+		}
+		if attempt == configs.MaxRetries {
+			break
+		}
+
+		if configs.DebugMode {
+			log.Warnf("Transient error (attempt %d/%d): %+v, retrying in %s", attempt+1, configs.MaxRetries, lastErr, backoff)
+		}
+		time.Sleep(backoff)
+
+		if reconnect != nil {
+			if err := reconnect(); err != nil {
+				lastErr = err
+				continue
+			}
+		}
+
+		backoff *= 2
+		if backoff > configs.MaxBackoff {
+			backoff = configs.MaxBackoff
+		}
+	}
+
+	return lastErr
+}
+
+// Mkdir ...
+func (c *ftpClient) Mkdir(path string) error {
+	return c.withRetry(c.reconnect, func() error { return c.session.Mkd(path) })
+}
+
+// PutFile opens localPath fresh on every attempt, since a prior attempt may
+// have already consumed part of the reader.
+func (c *ftpClient) PutFile(localPath, remotePath string) error {
+	return c.withRetry(c.reconnect, func() error {
+		file, err := os.Open(localPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				failf("Failed to close file, error: %+v", err)
+			}
+		}()
+		return c.session.Stor(remotePath, file)
+	})
+}
+
+// Stat assembles an os.FileInfo from the FTP SIZE/MDTM commands, which is all
+// sync()'s incremental comparison needs. Not retried: it's only used for the
+// incremental skip check, not the upload path itself.
+func (c *ftpClient) Stat(path string) (os.FileInfo, error) {
+	size, err := c.session.Size(path)
+	if err != nil {
+		return nil, err
+	}
+	modTime, err := c.session.ModTime(path)
+	if err != nil {
+		return nil, err
+	}
+	return remoteFileInfo{name: filepath.Base(path), size: size, modTime: modTime}, nil
+}
+
+func (c *ftpClient) ReadDir(path string) ([]os.FileInfo, error) { return c.session.ReadDir(path) }
+func (c *ftpClient) Remove(path string) error                   { return c.session.Dele(path) }
+func (c *ftpClient) Close() error                               { return c.session.Close() }
+
+// buildSSHClientConfig sets up key or password auth (a private key takes
+// precedence when given) and a host key callback: SSHKnownHostsFile if
+// provided, otherwise verification is refused unless NoCheckCertificate
+// opts into it, mirroring the FTPS side's secure-by-default posture.
+func buildSSHClientConfig(configs ConfigsModel) (*ssh.ClientConfig, error) {
+	var auth []ssh.AuthMethod
+
+	if configs.SSHPrivateKeyPath != "" {
+		keyBytes, err := ioutil.ReadFile(configs.SSHPrivateKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSHPrivateKeyPath: %s", err)
+		}
+
+		var signer ssh.Signer
+		if configs.SSHKeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(keyBytes, []byte(configs.SSHKeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(keyBytes)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SSH private key: %s", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	} else {
+		auth = append(auth, ssh.Password(configs.Password))
+	}
+
+	var hostKeyCallback ssh.HostKeyCallback
+	if configs.SSHKnownHostsFile != "" {
+		callback, err := knownhosts.New(configs.SSHKnownHostsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load SSHKnownHostsFile: %s", err)
+		}
+		hostKeyCallback = callback
+	} else if configs.NoCheckCertificate {
+		log.Warnf("SSHKnownHostsFile not set and no_check_certificate is true: connecting without host key verification")
+		hostKeyCallback = ssh.InsecureIgnoreHostKey()
+	} else {
+		return nil, fmt.Errorf("ssh_known_hosts_file not set: refusing to connect to an SFTP server without host key verification (set ssh_known_hosts_file, or set no_check_certificate to true to accept the risk)")
 	}
+
+	return &ssh.ClientConfig{
+		User:            configs.Username,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	}, nil
+}
+
+// sftpTransport implements Transport over github.com/pkg/sftp, so users can
+// switch a single Protocol field to upgrade from insecure FTP to SFTP. Like
+// ftpClient, it retries Mkdir/PutFile with exponential backoff, reconnecting
+// the underlying SSH connection first since a dead one won't recover on its
+// own.
+type sftpTransport struct {
+	configs ConfigsModel
+	sshConn *ssh.Client
+	client  *sftp.Client
+}
+
+func newSFTPTransport(configs ConfigsModel) (*sftpTransport, error) {
+	t := &sftpTransport{configs: configs}
+	if err := t.reconnect(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *sftpTransport) reconnect() error {
+	if t.client != nil {
+		_ = t.client.Close()
+	}
+	if t.sshConn != nil {
+		_ = t.sshConn.Close()
+	}
+
+	sshConfig, err := buildSSHClientConfig(t.configs)
+	if err != nil {
+		return err
+	}
+
+	sshConn, err := ssh.Dial("tcp", t.configs.HostName, sshConfig)
+	if err != nil {
+		return err
+	}
+
+	client, err := sftp.NewClient(sshConn)
+	if err != nil {
+		_ = sshConn.Close()
+		return err
+	}
+
+	t.sshConn = sshConn
+	t.client = client
+	return nil
+}
+
+// Mkdir reports the client's Mkdir error as-is: the SFTP protocol returns a
+// generic SSH_FX_FAILURE for an existing directory, indistinguishable from
+// other failures. sync() treats Mkdir errors as non-fatal (it's only used to
+// ensure the directory is there, not to detect whether it already was),
+// except for a symlinked directory, whose Mkdir error ends the walk.
+func (t *sftpTransport) Mkdir(path string) error {
+	return withRetry(t.configs, t.reconnect, func() error { return t.client.Mkdir(path) })
+}
+
+// PutFile opens localPath fresh on every attempt, since a prior attempt may
+// have already consumed part of the reader.
+func (t *sftpTransport) PutFile(localPath, remotePath string) error {
+	return withRetry(t.configs, t.reconnect, func() error {
+		local, err := os.Open(localPath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := local.Close(); err != nil {
+				failf("Failed to close file, error: %+v", err)
+			}
+		}()
+
+		remote, err := t.client.Create(remotePath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := remote.Close(); err != nil {
+				failf("Failed to close remote file, error: %+v", err)
+			}
+		}()
+
+		_, err = io.Copy(remote, local)
+		return err
+	})
+}
+
+func (t *sftpTransport) Stat(path string) (os.FileInfo, error)      { return t.client.Stat(path) }
+func (t *sftpTransport) ReadDir(path string) ([]os.FileInfo, error) { return t.client.ReadDir(path) }
+func (t *sftpTransport) Remove(path string) error                   { return t.client.Remove(path) }
+func (t *sftpTransport) Close() error {
+	closeErr := t.client.Close()
+	if err := t.sshConn.Close(); err != nil && closeErr == nil {
+		closeErr = err
+	}
+	return closeErr
+}
+
+// connectTransport dials the transport selected by Protocol. ftp and ftps
+// both go through ftpClient (FtpsMode picks plain/implicit/explicit TLS);
+// sftp gets its own connection and auth handling entirely.
+func connectTransport(configs ConfigsModel) (Transport, error) {
+	if configs.Protocol == "sftp" {
+		return newSFTPTransport(configs)
+	}
+	return newFtpClient(configs)
 }
 
 func main() {
@@ -100,16 +1020,9 @@ func main() {
 	fmt.Println()
 	log.Infof("Connecting to server...")
 
-	var ftp *goftp.FTP
-	var err error
-
 	configs.cleanHostName()
 
-	if !configs.DebugMode {
-		ftp, err = goftp.Connect(configs.HostName)
-	} else {
-		ftp, err = goftp.ConnectDbg(configs.HostName)
-	}
+	ftp, err := connectTransport(*configs)
 	if err != nil {
 		failf("Failed to connect to the ftp server, error: %+v", err)
 	}
@@ -121,16 +1034,7 @@ func main() {
 		}
 	}()
 
-	log.Donef("Connected")
-
-	fmt.Println()
-	log.Infof("Authenticating user...")
-
-	if err = ftp.Login(configs.Username, configs.Password); err != nil {
-		failf("Failed to login to the ftp server, error: %+v", err)
-	}
-
-	log.Donef("Successful")
+	log.Donef("Connected and authenticated")
 
 	fmt.Println()
 	log.Infof("Uploading...")
@@ -142,7 +1046,100 @@ func main() {
 	log.Donef("Done")
 }
 
-func (configs ConfigsModel) sync(ftp *goftp.FTP, localPath, remotePath string) error {
+// fileJob is a single local->remote file upload, queued for the worker pool.
+type fileJob struct {
+	localPath  string
+	remotePath string
+}
+
+// uploadResult carries the outcome of a single fileJob back from a worker.
+type uploadResult struct {
+	job fileJob
+	err error
+}
+
+// filterRule is a single gitignore-style include/exclude glob, matched
+// against a path relative to SourcePath so the rules are portable across
+// build agents.
+type filterRule struct {
+	pattern string
+	exclude bool
+}
+
+// buildFilterRules assembles the ordered rule set: FilterFrom's file first
+// (each line prefixed with + or -), followed by IncludeFilters and then
+// ExcludeFilters. Rules are evaluated in this order, last match wins.
+func buildFilterRules(configs ConfigsModel) ([]filterRule, error) {
+	var rules []filterRule
+
+	if configs.FilterFrom != "" {
+		fileRules, err := parseFilterFromFile(configs.FilterFrom)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, fileRules...)
+	}
+
+	for _, pattern := range configs.IncludeFilters {
+		rules = append(rules, filterRule{pattern: pattern, exclude: false})
+	}
+	for _, pattern := range configs.ExcludeFilters {
+		rules = append(rules, filterRule{pattern: pattern, exclude: true})
+	}
+
+	return rules, nil
+}
+
+// parseFilterFromFile reads a --filter-from style file: one pattern per
+// line, prefixed with + (include) or - (exclude). Blank lines and lines
+// starting with # are ignored.
+func parseFilterFromFile(path string) ([]filterRule, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FilterFrom file: %s", err)
+	}
+
+	var rules []filterRule
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "+"):
+			rules = append(rules, filterRule{pattern: strings.TrimSpace(line[1:]), exclude: false})
+		case strings.HasPrefix(line, "-"):
+			rules = append(rules, filterRule{pattern: strings.TrimSpace(line[1:]), exclude: true})
+		default:
+			return nil, fmt.Errorf("invalid FilterFrom line %q, must start with + or -", line)
+		}
+	}
+	return rules, nil
+}
+
+// matchFilterRules reports whether relPath should be included, per
+// gitignore-style last-match-wins semantics: a path with no matching rule
+// is included. relPath is matched both in full and by its base name, so a
+// pattern like "node_modules" or "*.log" matches regardless of depth.
+func matchFilterRules(rules []filterRule, relPath string) bool {
+	included := true
+	for _, rule := range rules {
+		matched, _ := filepath.Match(rule.pattern, relPath)
+		if !matched {
+			matched, _ = filepath.Match(rule.pattern, filepath.Base(relPath))
+		}
+		if matched {
+			included = !rule.exclude
+		}
+	}
+	return included
+}
+
+// sync walks the local tree once, creating the remote directory structure
+// serially (so workers never race to create the same parent), then uploads
+// the queued files through a bounded pool of worker connections.
+func (configs ConfigsModel) sync(ftp Transport, localPath, remotePath string) error {
 	fullPath, err := filepath.Abs(localPath)
 	if err != nil {
 		return err
@@ -166,13 +1163,28 @@ func (configs ConfigsModel) sync(ftp *goftp.FTP, localPath, remotePath string) e
 
 	for _, pItem := range remotePathsToMake {
 		mkdirPath = filepath.Join(mkdirPath, pItem)
-		if err := ftp.Mkd(mkdirPath); err != nil {
+		if err := ftp.Mkdir(mkdirPath); err != nil {
 			if configs.DebugMode {
 				log.Warnf("Warning: %+v", err)
 			}
 		}
 	}
 
+	filterRules, err := buildFilterRules(configs)
+	if err != nil {
+		return err
+	}
+
+	var jobs []fileJob
+	localFilesByDir := map[string]map[string]bool{}
+	if configs.DeleteExtraneous && localFileInfo.IsDir() {
+		// Seed the root even though the walk's fi.IsDir() case only registers
+		// subdirectories it visits: a source tree with no loose files at its
+		// top level would otherwise never register remotePath itself, and
+		// deleteExtraneous would silently skip cleaning it up.
+		localFilesByDir[remotePath] = map[string]bool{}
+	}
+
 	walkFunc := func(path string, fi os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -181,24 +1193,38 @@ func (configs ConfigsModel) sync(ftp *goftp.FTP, localPath, remotePath string) e
 		if err != nil {
 			return err
 		}
+
+		if path != fullPath && !matchFilterRules(filterRules, filepath.ToSlash(relPath)) {
+			if configs.DebugMode {
+				log.Warnf("Skipping %s, excluded by filter rules", path)
+			}
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		switch {
 		case fi.IsDir():
 			if path == fullPath {
 				return nil
 			}
 			rPath := filepath.Join(remotePath, relPath)
-			if err = ftp.Mkd(rPath); err != nil {
+			if err = ftp.Mkdir(rPath); err != nil {
 				if configs.DebugMode {
 					log.Warnf("Warning: %+v", err)
 				}
 			}
+			if configs.DeleteExtraneous {
+				localFilesByDir[rPath] = map[string]bool{}
+			}
 		case fi.Mode()&os.ModeSymlink == os.ModeSymlink:
 			fInfo, err := os.Stat(path)
 			if err != nil {
 				return err
 			}
 			if fInfo.IsDir() {
-				err = ftp.Mkd(relPath)
+				err = ftp.Mkdir(relPath)
 				return err
 			} else if fInfo.Mode()&os.ModeType != 0 {
 				return nil
@@ -211,38 +1237,195 @@ func (configs ConfigsModel) sync(ftp *goftp.FTP, localPath, remotePath string) e
 				rPath = filepath.Join(rPath, fi.Name())
 			}
 
-			// if file filter defined, check here
-			if len(SourcePathFilter) > 0 {
-				match, _ := regexp.MatchString(SourcePathFilter, path)
-				if !match {
-					if configs.DebugMode {
-						log.Warnf("Skipping file %s as not matched by regex pattern %s", path, SourcePathFilter)
-					}
-					return nil
+			if configs.DeleteExtraneous {
+				rDir := filepath.Dir(rPath)
+				if localFilesByDir[rDir] == nil {
+					localFilesByDir[rDir] = map[string]bool{}
 				}
+				localFilesByDir[rDir][filepath.Base(rPath)] = true
 			}
-			
 
-			if err = copyFile(ftp, path, rPath); err != nil {
-				return err
+			if configs.shouldSkipUpload(ftp, fi, rPath) {
+				if configs.DebugMode {
+					log.Printf("Skipping unchanged file %s", path)
+				}
+				return nil
 			}
+
+			jobs = append(jobs, fileJob{localPath: path, remotePath: rPath})
 		}
 		return nil
 	}
-	return filepath.Walk(fullPath, walkFunc)
+	if err := filepath.Walk(fullPath, walkFunc); err != nil {
+		return err
+	}
+
+	if configs.DeleteExtraneous {
+		configs.deleteExtraneous(ftp, localFilesByDir)
+	}
+
+	return configs.uploadAll(jobs)
 }
 
-func copyFile(ftp *goftp.FTP, localPath, serverPath string) (err error) {
-	var file *os.File
-	if file, err = os.Open(localPath); err != nil {
-		return err
+// shouldSkipUpload reports whether the remote file already matches the local
+// one, per the incremental SIZE/MDTM comparison. Overwrite=always and a
+// missing/unreadable remote file both mean: don't skip, upload it.
+func (configs ConfigsModel) shouldSkipUpload(ftp Transport, localFileInfo os.FileInfo, remotePath string) bool {
+	if !configs.Incremental || configs.Overwrite == "always" {
+		return false
 	}
-	defer func() {
-		err := file.Close()
+
+	remoteInfo, err := ftp.Stat(remotePath)
+	if err != nil {
+		return false
+	}
+
+	return remoteInfo.Size() == localFileInfo.Size() && !remoteInfo.ModTime().Before(localFileInfo.ModTime())
+}
+
+// deleteExtraneous removes remote files that no longer exist locally,
+// mirroring rsync's --delete for the directories visited during this sync.
+func (configs ConfigsModel) deleteExtraneous(ftp Transport, localFilesByDir map[string]map[string]bool) {
+	for rDir, localFiles := range localFilesByDir {
+		remoteEntries, err := ftp.ReadDir(rDir)
 		if err != nil {
-			failf("Failed to close file, error: %+v", err)
+			if configs.DebugMode {
+				log.Warnf("Warning: failed to list %s for --delete-extraneous: %+v", rDir, err)
+			}
+			continue
+		}
+
+		for _, entry := range remoteEntries {
+			if entry.IsDir() || localFiles[entry.Name()] {
+				continue
+			}
+			rPath := filepath.Join(rDir, entry.Name())
+			if err := ftp.Remove(rPath); err != nil {
+				if configs.DebugMode {
+					log.Warnf("Warning: failed to delete extraneous file %s: %+v", rPath, err)
+				}
+				continue
+			}
+			log.Printf("Deleted extraneous remote file: %s", rPath)
+		}
+	}
+}
+
+// connectInterval paces how fast uploadAll's workers are allowed to open new
+// connections, so a high concurrency value doesn't slam the server with
+// dozens of simultaneous control connections.
+const connectInterval = 200 * time.Millisecond
+
+// newConnectPacer returns a channel that yields tokens: a first one
+// immediately, then one every connectInterval, for a total of tokens. Each
+// worker receives from it before connecting, spreading connection setup
+// out over roughly (tokens-1)*connectInterval instead of all at once.
+func newConnectPacer(tokens int) <-chan struct{} {
+	ch := make(chan struct{}, tokens)
+	ch <- struct{}{}
+
+	go func() {
+		defer close(ch)
+		if tokens <= 1 {
+			return
+		}
+		ticker := time.NewTicker(connectInterval)
+		defer ticker.Stop()
+		for i := 1; i < tokens; i++ {
+			<-ticker.C
+			ch <- struct{}{}
+		}
+	}()
+
+	return ch
+}
+
+// uploadAll dispatches jobs to a bounded pool of worker goroutines, each
+// holding its own authenticated connection, and aggregates per-file errors
+// instead of failing fast on the first one.
+func (configs ConfigsModel) uploadAll(jobs []fileJob) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	workerCount := configs.Concurrency
+	if workerCount <= 0 || workerCount > len(jobs) {
+		workerCount = len(jobs)
+	}
+
+	jobsCh := make(chan fileJob)
+	resultsCh := make(chan uploadResult, len(jobs))
+	pacer := newConnectPacer(workerCount)
+	var connectFailures int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			configs.uploadWorker(worker, jobsCh, resultsCh, pacer, &connectFailures, int32(workerCount))
+		}(i)
+	}
+
+	go func() {
+		for _, job := range jobs {
+			jobsCh <- job
+		}
+		close(jobsCh)
+	}()
+
+	wg.Wait()
+	close(resultsCh)
+
+	var failures []string
+	for result := range resultsCh {
+		if result.err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %+v", result.job.localPath, result.err))
+			continue
+		}
+		if configs.DebugMode {
+			log.Donef("Uploaded %s", result.job.localPath)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d/%d file(s) failed to upload:\n%s", len(failures), len(jobs), strings.Join(failures, "\n"))
+	}
+
+	return nil
+}
+
+// uploadWorker waits for its turn on pacer before opening a connection, then
+// holds that single connection for its whole lifetime and drains
+// jobsCh, acting as one slot of the bounded connection pool.
+//
+// If it can't connect, it returns without draining jobsCh, leaving every job
+// for the other workers rather than failing jobs it never attempted —
+// except when every worker in the pool fails to connect, in which case
+// there's no one left to drain jobsCh, so the last one to fail reports the
+// rest as failed itself instead of leaving the dispatcher goroutine
+// (main.go's uploadAll) blocked forever.
+func (configs ConfigsModel) uploadWorker(worker int, jobsCh <-chan fileJob, resultsCh chan<- uploadResult, pacer <-chan struct{}, connectFailures *int32, workerCount int32) {
+	<-pacer
+
+	ftp, err := connectTransport(configs)
+	if err != nil {
+		if atomic.AddInt32(connectFailures, 1) == workerCount {
+			for job := range jobsCh {
+				resultsCh <- uploadResult{job: job, err: fmt.Errorf("worker %d: failed to connect: %s", worker, err)}
+			}
+		} else if configs.DebugMode {
+			log.Warnf("Worker %d: failed to connect, error: %+v", worker, err)
+		}
+		return
+	}
+	defer func() {
+		if err := ftp.Close(); err != nil && configs.DebugMode {
+			log.Warnf("Worker %d: failed to close connection, error: %+v", worker, err)
 		}
 	}()
 
-	return ftp.Stor(serverPath, file)
+	for job := range jobsCh {
+		resultsCh <- uploadResult{job: job, err: ftp.PutFile(job.localPath, job.remotePath)}
+	}
 }
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseUnixListLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantErr bool
+		size    int64
+		isDir   bool
+		fiName  string
+	}{
+		{
+			name:   "file",
+			line:   "-rw-r--r-- 1 owner group 1234 Jan 02 15:04 filename.txt",
+			size:   1234,
+			isDir:  false,
+			fiName: "filename.txt",
+		},
+		{
+			name:   "directory",
+			line:   "drwxr-xr-x 2 owner group 4096 Jan 02 15:04 subdir",
+			size:   4096,
+			isDir:  true,
+			fiName: "subdir",
+		},
+		{
+			name:   "name with spaces",
+			line:   "-rw-r--r-- 1 owner group 10 Jan 02 15:04 my file.txt",
+			size:   10,
+			isDir:  false,
+			fiName: "my file.txt",
+		},
+		{
+			name:    "too few fields",
+			line:    "-rw-r--r-- 1 owner group 10",
+			wantErr: true,
+		},
+		{
+			name:    "unparsable size",
+			line:    "-rw-r--r-- 1 owner group notasize Jan 02 15:04 file.txt",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fi, err := parseUnixListLine(tt.line)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if fi.Name() != tt.fiName {
+				t.Errorf("Name() = %q, want %q", fi.Name(), tt.fiName)
+			}
+			if fi.Size() != tt.size {
+				t.Errorf("Size() = %d, want %d", fi.Size(), tt.size)
+			}
+			if fi.IsDir() != tt.isDir {
+				t.Errorf("IsDir() = %t, want %t", fi.IsDir(), tt.isDir)
+			}
+		})
+	}
+}
+
+func TestIsTransientErr(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "io.EOF", err: io.EOF, want: true},
+		{name: "wrapped io.EOF", err: fmt.Errorf("stor failed: %w", io.EOF), want: true},
+		{name: "net.OpError", err: &net.OpError{Op: "dial", Err: errors.New("boom")}, want: true},
+		{name: "textproto 4xx", err: &textproto.Error{Code: 421, Msg: "Service not available"}, want: true},
+		{name: "textproto 5xx", err: &textproto.Error{Code: 550, Msg: "Permission denied"}, want: false},
+		{name: "plain 421 string", err: errors.New("421 Service not available"), want: true},
+		{name: "plain permanent error", err: errors.New("530 Login incorrect"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientErr(tt.err); got != tt.want {
+				t.Errorf("isTransientErr(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchFilterRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []filterRule
+		relPath string
+		want    bool
+	}{
+		{name: "no rules includes everything", rules: nil, relPath: "a/b.txt", want: true},
+		{
+			name:    "single exclude",
+			rules:   []filterRule{{pattern: "*.log", exclude: true}},
+			relPath: "app.log",
+			want:    false,
+		},
+		{
+			name:    "exclude matches base name at depth",
+			rules:   []filterRule{{pattern: "node_modules", exclude: true}},
+			relPath: "src/node_modules",
+			want:    false,
+		},
+		{
+			name: "later rule wins",
+			rules: []filterRule{
+				{pattern: "*.txt", exclude: true},
+				{pattern: "keep.txt", exclude: false},
+			},
+			relPath: "keep.txt",
+			want:    true,
+		},
+		{
+			name: "unrelated rule doesn't match",
+			rules: []filterRule{
+				{pattern: "*.log", exclude: true},
+			},
+			relPath: "keep.txt",
+			want:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchFilterRules(tt.rules, tt.relPath); got != tt.want {
+				t.Errorf("matchFilterRules(%v, %q) = %t, want %t", tt.rules, tt.relPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterFromFile(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("valid rules", func(t *testing.T) {
+		path := filepath.Join(dir, "valid.filter")
+		content := "# comment\n+ *.txt\n-*.log\n\n"
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write fixture: %s", err)
+		}
+
+		rules, err := parseFilterFromFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		want := []filterRule{
+			{pattern: "*.txt", exclude: false},
+			{pattern: "*.log", exclude: true},
+		}
+		if len(rules) != len(want) {
+			t.Fatalf("got %d rules, want %d", len(rules), len(want))
+		}
+		for i := range want {
+			if rules[i] != want[i] {
+				t.Errorf("rule %d = %+v, want %+v", i, rules[i], want[i])
+			}
+		}
+	})
+
+	t.Run("invalid line", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.filter")
+		if err := ioutil.WriteFile(path, []byte("*.txt\n"), 0644); err != nil {
+			t.Fatalf("failed to write fixture: %s", err)
+		}
+
+		if _, err := parseFilterFromFile(path); err == nil {
+			t.Fatalf("expected an error for a line without a +/- prefix")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := parseFilterFromFile(filepath.Join(dir, "missing.filter")); err == nil {
+			t.Fatalf("expected an error for a missing file")
+		}
+	})
+}
+
+// fakeFileInfo is a minimal os.FileInfo for test fixtures.
+type fakeFileInfo struct {
+	size    int64
+	modTime time.Time
+}
+
+func (fi fakeFileInfo) Name() string       { return "fake" }
+func (fi fakeFileInfo) Size() int64        { return fi.size }
+func (fi fakeFileInfo) Mode() os.FileMode  { return 0 }
+func (fi fakeFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi fakeFileInfo) IsDir() bool        { return false }
+func (fi fakeFileInfo) Sys() interface{}   { return nil }
+
+// fakeTransport is a Transport stub whose Stat result is configured per test.
+type fakeTransport struct {
+	statInfo os.FileInfo
+	statErr  error
+}
+
+func (t *fakeTransport) Mkdir(path string) error                    { return nil }
+func (t *fakeTransport) PutFile(localPath, remotePath string) error { return nil }
+func (t *fakeTransport) Stat(path string) (os.FileInfo, error)      { return t.statInfo, t.statErr }
+func (t *fakeTransport) ReadDir(path string) ([]os.FileInfo, error) { return nil, nil }
+func (t *fakeTransport) Remove(path string) error                   { return nil }
+func (t *fakeTransport) Close() error                               { return nil }
+
+func TestShouldSkipUpload(t *testing.T) {
+	now := time.Now()
+	local := fakeFileInfo{size: 100, modTime: now}
+
+	tests := []struct {
+		name    string
+		configs ConfigsModel
+		remote  *fakeTransport
+		want    bool
+	}{
+		{
+			name:    "not incremental always uploads",
+			configs: ConfigsModel{Incremental: false},
+			remote:  &fakeTransport{statInfo: fakeFileInfo{size: 100, modTime: now}},
+			want:    false,
+		},
+		{
+			name:    "overwrite always bypasses the comparison",
+			configs: ConfigsModel{Incremental: true, Overwrite: "always"},
+			remote:  &fakeTransport{statInfo: fakeFileInfo{size: 100, modTime: now}},
+			want:    false,
+		},
+		{
+			name:    "remote stat error uploads",
+			configs: ConfigsModel{Incremental: true, Overwrite: "when_changed"},
+			remote:  &fakeTransport{statErr: errors.New("no such file")},
+			want:    false,
+		},
+		{
+			name:    "matching size and mtime skips",
+			configs: ConfigsModel{Incremental: true, Overwrite: "when_changed"},
+			remote:  &fakeTransport{statInfo: fakeFileInfo{size: 100, modTime: now}},
+			want:    true,
+		},
+		{
+			name:    "different size uploads",
+			configs: ConfigsModel{Incremental: true, Overwrite: "when_changed"},
+			remote:  &fakeTransport{statInfo: fakeFileInfo{size: 99, modTime: now}},
+			want:    false,
+		},
+		{
+			name:    "older remote mtime uploads",
+			configs: ConfigsModel{Incremental: true, Overwrite: "when_changed"},
+			remote:  &fakeTransport{statInfo: fakeFileInfo{size: 100, modTime: now.Add(-time.Hour)}},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.configs.shouldSkipUpload(tt.remote, local, "/remote/path"); got != tt.want {
+				t.Errorf("shouldSkipUpload() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}